@@ -0,0 +1,220 @@
+package midjourneyapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CallbackSecretHeader is the header CallbackServer checks against its
+// configured secret, when one is set.
+const CallbackSecretHeader = "X-Callback-Secret"
+
+type callbackType string
+
+const (
+	callbackTypeImagine  callbackType = "imagine"
+	callbackTypeDescribe callbackType = "describe"
+	callbackTypeSeed     callbackType = "seed"
+	callbackTypeUpscale  callbackType = "upscale"
+)
+
+// CallbackPayload is the envelope Midjourney posts to a callbackURL when a
+// task completes or fails. Only the fields relevant to the task's type are
+// populated.
+type CallbackPayload struct {
+	TaskId     string   `json:"taskId"`
+	Status     string   `json:"status,omitempty"`
+	Percentage float64  `json:"percentage,omitempty"`
+	ImageURL   string   `json:"image_url,omitempty"`
+	Content    []string `json:"content,omitempty"`
+	Seed       string   `json:"seed,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// ImagineResult converts the payload to the shape ImagineResult would have
+// returned, for use inside OnImagineComplete.
+func (p CallbackPayload) ImagineResult() *ImagineResultResponse {
+	return &ImagineResultResponse{
+		ResultResponse: ResultResponse{Status: p.Status, Percentage: p.Percentage},
+		ImageURL:       p.ImageURL,
+	}
+}
+
+// DescribeResult converts the payload to the shape DescribeResult would have
+// returned, for use inside OnDescribeComplete.
+func (p CallbackPayload) DescribeResult() *DescribeResultResponse {
+	return &DescribeResultResponse{
+		ResultResponse: ResultResponse{Status: p.Status, Percentage: p.Percentage},
+		Content:        p.Content,
+	}
+}
+
+// SeedResult converts the payload to the shape SeedResult would have
+// returned, for use inside OnSeedComplete.
+func (p CallbackPayload) SeedResult() *SeedResultResponse {
+	return &SeedResultResponse{
+		ResultResponse: ResultResponse{Status: p.Status, Percentage: p.Percentage},
+		Seed:           p.Seed,
+	}
+}
+
+// CallbackServer is an http.Handler that decodes Midjourney's callback
+// payload and dispatches it to per-task handlers registered via Register,
+// as well as to the typed On*Complete hooks.
+type CallbackServer struct {
+	baseURL string
+	secret  string
+
+	mu       sync.Mutex
+	handlers map[string]func(CallbackPayload)
+
+	OnImagineComplete  func(CallbackPayload)
+	OnDescribeComplete func(CallbackPayload)
+	OnSeedComplete     func(CallbackPayload)
+	OnUpscaleComplete  func(CallbackPayload)
+	OnError            func(CallbackPayload)
+}
+
+type CallbackServerOption func(*CallbackServer)
+
+// WithCallbackSecret requires incoming callbacks to carry CallbackSecretHeader
+// set to secret, rejecting any request that doesn't with 401.
+func WithCallbackSecret(secret string) CallbackServerOption {
+	return func(s *CallbackServer) { s.secret = secret }
+}
+
+// NewCallbackServer builds a CallbackServer that receives callbacks at
+// baseURL, the publicly reachable address this server's handler is mounted
+// at (e.g. "https://example.com/mj-callback").
+func NewCallbackServer(baseURL string, opts ...CallbackServerOption) *CallbackServer {
+	s := &CallbackServer{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		handlers: make(map[string]func(CallbackPayload)),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Register installs a one-shot handler for taskId: it fires the next time a
+// callback with a matching taskId arrives, then is removed.
+func (s *CallbackServer) Register(taskId string, handler func(CallbackPayload)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[taskId] = handler
+}
+
+func (s *CallbackServer) unregister(taskId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.handlers, taskId)
+}
+
+func (s *CallbackServer) urlFor(t callbackType) string {
+	return s.baseURL + "?type=" + string(t)
+}
+
+func (s *CallbackServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.secret != "" && r.Header.Get(CallbackSecretHeader) != s.secret {
+		http.Error(w, "invalid callback secret", http.StatusUnauthorized)
+		return
+	}
+
+	var payload CallbackPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "invalid callback payload", http.StatusBadRequest)
+		return
+	}
+
+	s.dispatch(callbackType(r.URL.Query().Get("type")), payload)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *CallbackServer) dispatch(t callbackType, payload CallbackPayload) {
+	s.mu.Lock()
+	handler, ok := s.handlers[payload.TaskId]
+	if ok {
+		delete(s.handlers, payload.TaskId)
+	}
+	s.mu.Unlock()
+
+	if ok {
+		handler(payload)
+	}
+
+	if payload.Error != "" {
+		if s.OnError != nil {
+			s.OnError(payload)
+		}
+		return
+	}
+
+	switch t {
+	case callbackTypeImagine:
+		if s.OnImagineComplete != nil {
+			s.OnImagineComplete(payload)
+		}
+	case callbackTypeDescribe:
+		if s.OnDescribeComplete != nil {
+			s.OnDescribeComplete(payload)
+		}
+	case callbackTypeSeed:
+		if s.OnSeedComplete != nil {
+			s.OnSeedComplete(payload)
+		}
+	case callbackTypeUpscale:
+		if s.OnUpscaleComplete != nil {
+			s.OnUpscaleComplete(payload)
+		}
+	}
+}
+
+// ErrNoCallbackServer is returned by ImagineWithCallback when the client has
+// no CallbackServer configured via WithCallbackServer.
+var ErrNoCallbackServer = errors.New("midjourneyapi: no callback server configured")
+
+// WithCallbackServer sets the CallbackServer used by ImagineWithCallback (and
+// future callback-based helpers) to receive asynchronous results.
+func (self *Client) WithCallbackServer(s *CallbackServer) *Client {
+	self.callbackServer = s
+	return self
+}
+
+// ImagineWithCallback starts an Imagine task with a callback URL generated by
+// the client's configured CallbackServer, registers a one-shot handler for
+// it, and blocks until the callback fires or ctx is done.
+func (self *Client) ImagineWithCallback(ctx context.Context, prompt string, mode ImagineMode) (*ImagineResultResponse, error) {
+	if self.callbackServer == nil {
+		return nil, ErrNoCallbackServer
+	}
+
+	taskId, err := self.Imagine(prompt, mode, self.callbackServer.urlFor(callbackTypeImagine))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(chan CallbackPayload, 1)
+	self.callbackServer.Register(taskId, func(p CallbackPayload) {
+		result <- p
+	})
+	defer self.callbackServer.unregister(taskId)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case payload := <-result:
+		if payload.Error != "" {
+			return nil, fmt.Errorf("midjourneyapi: task failed: %s", payload.Error)
+		}
+		return payload.ImagineResult(), nil
+	}
+}
@@ -0,0 +1,127 @@
+package midjourneyapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeImagineTransport stands in for the real Midjourney API, always
+// answering /imagine with a fixed task ID so the callback delivery path can
+// be exercised without a network call.
+type fakeImagineTransport struct {
+	taskId string
+}
+
+func (f fakeImagineTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	body := `{"taskId":"` + f.taskId + `"}`
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func TestCallbackServer_RegisterDispatchesOneShotHandler(t *testing.T) {
+	server := NewCallbackServer("https://example.com/callback")
+
+	received := make(chan CallbackPayload, 1)
+	server.Register("task-1", func(p CallbackPayload) { received <- p })
+
+	body, _ := json.Marshal(CallbackPayload{TaskId: "task-1", Status: "finished", ImageURL: "https://img"})
+	req := httptest.NewRequest(http.MethodPost, server.urlFor(callbackTypeImagine), bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	select {
+	case p := <-received:
+		if p.TaskId != "task-1" || p.ImageURL != "https://img" {
+			t.Fatalf("unexpected payload: %+v", p)
+		}
+	default:
+		t.Fatal("handler was not invoked")
+	}
+}
+
+func TestCallbackServer_RejectsWrongSecret(t *testing.T) {
+	server := NewCallbackServer("https://example.com/callback", WithCallbackSecret("s3cr3t"))
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/callback", bytes.NewReader([]byte(`{}`)))
+	rec := httptest.NewRecorder()
+
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestCallbackServer_DispatchesTypedHook(t *testing.T) {
+	server := NewCallbackServer("https://example.com/callback")
+
+	done := make(chan CallbackPayload, 1)
+	server.OnDescribeComplete = func(p CallbackPayload) { done <- p }
+
+	body, _ := json.Marshal(CallbackPayload{TaskId: "task-2", Status: "finished", Content: []string{"a prompt"}})
+	req := httptest.NewRequest(http.MethodPost, server.urlFor(callbackTypeDescribe), bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	server.ServeHTTP(rec, req)
+
+	select {
+	case p := <-done:
+		if p.DescribeResult().Content[0] != "a prompt" {
+			t.Fatalf("unexpected payload: %+v", p)
+		}
+	default:
+		t.Fatal("OnDescribeComplete was not invoked")
+	}
+}
+
+func TestClient_ImagineWithCallback_NoServerConfigured(t *testing.T) {
+	client := NewClient("key")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	if _, err := client.ImagineWithCallback(ctx, "a prompt", ImagineModeFast); err != ErrNoCallbackServer {
+		t.Fatalf("expected ErrNoCallbackServer, got %v", err)
+	}
+}
+
+func TestClient_ImagineWithCallback_ReturnsErrorOnTaskFailure(t *testing.T) {
+	server := NewCallbackServer("https://example.com/callback")
+
+	httpClient := &http.Client{Transport: fakeImagineTransport{taskId: "task-err"}}
+	client := NewClient("key", WithHTTPClient(httpClient)).WithCallbackServer(server)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+
+		body, _ := json.Marshal(CallbackPayload{TaskId: "task-err", Status: "failed", Error: "banned prompt"})
+		req := httptest.NewRequest(http.MethodPost, server.urlFor(callbackTypeImagine), bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		server.ServeHTTP(rec, req)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := client.ImagineWithCallback(ctx, "a prompt", ImagineModeFast); err == nil {
+		t.Fatal("expected an error for a failed task")
+	} else if !strings.Contains(err.Error(), "banned prompt") {
+		t.Fatalf("expected error to mention the failure reason, got %v", err)
+	}
+}
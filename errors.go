@@ -0,0 +1,85 @@
+package midjourneyapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// APIError represents a non-2xx response from the Midjourney API.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RawBody    []byte
+
+	// RetryAfter is populated from the Retry-After header on 429 responses,
+	// when present.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("midjourneyapi: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("midjourneyapi: %d: %s", e.StatusCode, string(e.RawBody))
+}
+
+// Is lets errors.Is match an *APIError against the sentinel errors below
+// based on its status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrTaskNotFound:
+		return e.StatusCode == http.StatusNotFound
+	default:
+		return false
+	}
+}
+
+var (
+	ErrUnauthorized = errors.New("midjourneyapi: unauthorized")
+	ErrRateLimited  = errors.New("midjourneyapi: rate limited")
+	ErrTaskNotFound = errors.New("midjourneyapi: task not found")
+)
+
+// apiErrorEnvelope is the documented JSON shape of an error response body.
+type apiErrorEnvelope struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// checkResponse returns an *APIError if res did not succeed, decoding the
+// documented error envelope from body when its Content-Type is JSON.
+func checkResponse(res *http.Response, body []byte) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	apiErr := &APIError{StatusCode: res.StatusCode, RawBody: body}
+
+	if mt, _, err := mime.ParseMediaType(res.Header.Get("Content-Type")); err == nil && mt == "application/json" {
+		var envelope apiErrorEnvelope
+		if err := json.Unmarshal(body, &envelope); err == nil {
+			apiErr.Code = envelope.Code
+			apiErr.Message = envelope.Message
+		}
+	}
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		if retryAfter := res.Header.Get("Retry-After"); retryAfter != "" {
+			if secs, err := strconv.Atoi(retryAfter); err == nil {
+				apiErr.RetryAfter = time.Duration(secs) * time.Second
+			}
+		}
+	}
+
+	return apiErr
+}
@@ -0,0 +1,63 @@
+package midjourneyapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func newResponse(status int, contentType string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{contentType}},
+	}
+}
+
+func TestCheckResponse_OkStatusReturnsNil(t *testing.T) {
+	if err := checkResponse(newResponse(http.StatusOK, "application/json"), []byte(`{}`)); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+func TestCheckResponse_DecodesJSONErrorEnvelope(t *testing.T) {
+	body := []byte(`{"code":"invalid_prompt","message":"prompt is empty"}`)
+	err := checkResponse(newResponse(http.StatusBadRequest, "application/json; charset=utf-8"), body)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.Code != "invalid_prompt" || apiErr.Message != "prompt is empty" {
+		t.Fatalf("unexpected envelope fields: %+v", apiErr)
+	}
+}
+
+func TestCheckResponse_MatchesSentinelErrors(t *testing.T) {
+	cases := []struct {
+		status int
+		target error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusNotFound, ErrTaskNotFound},
+	}
+
+	for _, c := range cases {
+		err := checkResponse(newResponse(c.status, "text/plain"), nil)
+		if !errors.Is(err, c.target) {
+			t.Fatalf("status %d: expected errors.Is to match %v, got %v", c.status, c.target, err)
+		}
+	}
+}
+
+func TestCheckResponse_ParsesRetryAfter(t *testing.T) {
+	res := newResponse(http.StatusTooManyRequests, "text/plain")
+	res.Header.Set("Retry-After", "30")
+
+	var apiErr *APIError
+	if err := checkResponse(res, nil); !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	} else if apiErr.RetryAfter.Seconds() != 30 {
+		t.Fatalf("expected 30s retry-after, got %v", apiErr.RetryAfter)
+	}
+}
@@ -0,0 +1,139 @@
+package midjourneyapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Option configures a Client built by NewClient.
+type Option func(*Client)
+
+// WithHTTPClient sets the *http.Client used for requests, in place of
+// http.DefaultClient. Useful for custom timeouts, transports, or proxies.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// BackoffFunc computes the delay before the given retry attempt (0-based).
+type BackoffFunc func(attempt int) time.Duration
+
+// DefaultBackoff doubles the delay each attempt, starting at 200ms and
+// capping at 5s.
+func DefaultBackoff(attempt int) time.Duration {
+	d := 200 * time.Millisecond * time.Duration(uint(1)<<uint(attempt))
+	if d > 5*time.Second {
+		d = 5 * time.Second
+	}
+	return d
+}
+
+// WithRetry retries requests that fail with a network error, a 5xx, or a
+// 429, up to maxAttempts times total. A 429's Retry-After header, when
+// present, takes priority over backoff. Pass a nil backoff to use
+// DefaultBackoff.
+func WithRetry(maxAttempts int, backoff BackoffFunc) Option {
+	return func(c *Client) {
+		c.maxAttempts = maxAttempts
+		if backoff != nil {
+			c.backoff = backoff
+		}
+	}
+}
+
+// WithRateLimit caps outgoing requests to rps requests per second, allowing
+// bursts up to burst.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) { c.limiter = rate.NewLimiter(rate.Limit(rps), burst) }
+}
+
+func shouldRetry(statusCode int) bool {
+	return statusCode >= 500 || statusCode == http.StatusTooManyRequests
+}
+
+func retryAfterDelay(res *http.Response, fallback time.Duration) time.Duration {
+	if res.StatusCode != http.StatusTooManyRequests {
+		return fallback
+	}
+
+	if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+
+	return fallback
+}
+
+// sleep waits out d, returning early with ctx.Err() if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// do executes req, applying the client's rate limit and retry policy, and
+// returns the fully-read response body alongside the final *http.Response
+// (for its status code and headers).
+func (self *Client) do(req *http.Request) ([]byte, *http.Response, error) {
+	if self.limiter != nil {
+		if err := self.limiter.Wait(req.Context()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	attempts := self.maxAttempts + 1
+
+	var (
+		res *http.Response
+		err error
+	)
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, berr := req.GetBody()
+			if berr != nil {
+				return nil, nil, berr
+			}
+			req.Body = body
+		}
+
+		res, err = self.httpClient.Do(req)
+		if err != nil {
+			if attempt == attempts-1 {
+				return nil, nil, err
+			}
+			if serr := sleep(req.Context(), self.backoff(attempt)); serr != nil {
+				return nil, nil, serr
+			}
+			continue
+		}
+
+		if !shouldRetry(res.StatusCode) || attempt == attempts-1 {
+			break
+		}
+
+		delay := retryAfterDelay(res, self.backoff(attempt))
+		res.Body.Close()
+		if serr := sleep(req.Context(), delay); serr != nil {
+			return nil, nil, serr
+		}
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return body, res, nil
+}
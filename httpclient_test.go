@@ -0,0 +1,112 @@
+package midjourneyapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_RetriesOn5xxThenSucceeds(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"taskId":"abc"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", WithRetry(5, func(attempt int) time.Duration { return 0 }))
+
+	var result ImagineResponse
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("{}"))
+	body, res, err := client.do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkResponse(res, body); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+	if result.TaskId != "abc" {
+		t.Fatalf("unexpected taskId: %s", result.TaskId)
+	}
+}
+
+func TestClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient("key", WithRetry(2, func(attempt int) time.Duration { return 0 }))
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("{}"))
+	body, res, err := client.do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkResponse(res, body); err == nil {
+		t.Fatal("expected an error for a persistent 500")
+	}
+
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts (1 + 2 retries), got %d", attempts)
+	}
+}
+
+func TestClient_BackoffSleepStopsOnContextCancellation(t *testing.T) {
+	requested := make(chan struct{}, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requested <- struct{}{}:
+		default:
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	// A long backoff means do only returns quickly if the context
+	// cancellation interrupts the sleep rather than time.Sleep ignoring it.
+	client := NewClient("key", WithRetry(5, func(attempt int) time.Duration { return time.Hour }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("{}"))
+	req = req.WithContext(ctx)
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := client.do(req)
+		done <- err
+	}()
+
+	<-requested
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("do did not return promptly after context cancellation")
+	}
+}
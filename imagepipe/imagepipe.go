@@ -0,0 +1,164 @@
+// Package imagepipe provides a small pre-upload image transformation
+// pipeline for the midjourneyapi client: resizing to a maximum dimension,
+// re-encoding to JPEG (which also strips EXIF metadata), and optionally
+// flattening opaque PNGs to JPEG so callers stay under Midjourney's
+// per-image size limits without hand-rolling image encoding themselves.
+package imagepipe
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	_ "image/gif"
+
+	_ "golang.org/x/image/webp"
+)
+
+// Pipeline applies a fixed set of transformations to every image it
+// processes. Build one with New, or use Default for the settings the
+// client falls back to.
+type Pipeline struct {
+	maxDimension     int
+	jpegQuality      int
+	convertPNGToJPEG bool
+}
+
+// Option configures a Pipeline built by New.
+type Option func(*Pipeline)
+
+// WithMaxDimension caps the longest side of the output image, in pixels.
+// Images already within the limit are left at their original size.
+func WithMaxDimension(px int) Option {
+	return func(p *Pipeline) { p.maxDimension = px }
+}
+
+// WithJPEGQuality sets the quality (1-100) used when encoding to JPEG.
+func WithJPEGQuality(quality int) Option {
+	return func(p *Pipeline) { p.jpegQuality = quality }
+}
+
+// WithPNGToJPEG controls whether opaque PNGs (no alpha channel) are
+// converted to JPEG. PNGs with transparency are always kept as PNG.
+func WithPNGToJPEG(convert bool) Option {
+	return func(p *Pipeline) { p.convertPNGToJPEG = convert }
+}
+
+// New builds a Pipeline, starting from Default's settings and applying opts
+// on top.
+func New(opts ...Option) *Pipeline {
+	p := &Pipeline{
+		maxDimension:     2048,
+		jpegQuality:      85,
+		convertPNGToJPEG: true,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Default returns the pipeline used when a Client has none configured:
+// resize to 2048px on the longest side, re-encode as JPEG at quality 85,
+// and convert opaque PNGs to JPEG.
+func Default() *Pipeline {
+	return New()
+}
+
+// Process decodes r, resizes it to fit within the pipeline's maximum
+// dimension, and re-encodes it, returning the resulting bytes and their
+// content type. Re-encoding also strips any EXIF metadata embedded in the
+// source image.
+func (p *Pipeline) Process(r io.Reader) ([]byte, string, error) {
+	src, format, err := image.Decode(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("imagepipe: decode image: %w", err)
+	}
+
+	resized := resizeToMax(src, p.maxDimension)
+
+	asJPEG := true
+	if format == "png" {
+		asJPEG = p.convertPNGToJPEG && isOpaque(resized)
+	}
+
+	return encode(resized, asJPEG, p.jpegQuality)
+}
+
+func resizeToMax(img image.Image, maxDimension int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if maxDimension <= 0 || (width <= maxDimension && height <= maxDimension) {
+		return img
+	}
+
+	scale := float64(maxDimension) / float64(width)
+	if height > width {
+		scale = float64(maxDimension) / float64(height)
+	}
+
+	newWidth := maxInt(1, int(float64(width)*scale))
+	newHeight := maxInt(1, int(float64(height)*scale))
+
+	dst := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < newHeight; y++ {
+		srcY := bounds.Min.Y + y*height/newHeight
+		for x := 0; x < newWidth; x++ {
+			srcX := bounds.Min.X + x*width/newWidth
+			dst.Set(x, y, img.At(srcX, srcY))
+		}
+	}
+
+	return dst
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+type opaquer interface {
+	Opaque() bool
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(opaquer); ok {
+		return o.Opaque()
+	}
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			_, _, _, a := img.At(x, y).RGBA()
+			if a != 0xffff {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func encode(img image.Image, asJPEG bool, quality int) ([]byte, string, error) {
+	var buf bytes.Buffer
+
+	if asJPEG {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, "", fmt.Errorf("imagepipe: encode jpeg: %w", err)
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", fmt.Errorf("imagepipe: encode png: %w", err)
+	}
+	return buf.Bytes(), "image/png", nil
+}
@@ -0,0 +1,141 @@
+package imagepipe
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+)
+
+func encodePNG(t *testing.T, img image.Image) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func solidRGBA(w, h int, c color.Color, alpha bool) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if alpha && x < w/2 {
+				img.Set(x, y, color.RGBA{0, 0, 0, 0})
+			} else {
+				img.Set(x, y, c)
+			}
+		}
+	}
+	return img
+}
+
+func TestPipeline_ResizesOversizedImage(t *testing.T) {
+	src := solidRGBA(4000, 2000, color.RGBA{255, 0, 0, 255}, false)
+
+	p := New(WithMaxDimension(1024), WithJPEGQuality(90))
+	data, contentType, err := p.Process(encodePNG(t, src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if contentType != "image/jpeg" {
+		t.Fatalf("expected image/jpeg, got %s", contentType)
+	}
+
+	decoded, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bounds := decoded.Bounds()
+	if bounds.Dx() != 1024 || bounds.Dy() != 512 {
+		t.Fatalf("expected 1024x512, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+}
+
+func TestPipeline_LeavesSmallImageUnscaled(t *testing.T) {
+	src := solidRGBA(100, 50, color.RGBA{0, 255, 0, 255}, false)
+
+	p := Default()
+	data, _, err := p.Process(encodePNG(t, src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bounds := decoded.Bounds()
+	if bounds.Dx() != 100 || bounds.Dy() != 50 {
+		t.Fatalf("expected 100x50, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+}
+
+func TestPipeline_KeepsTransparentPNGAsPNG(t *testing.T) {
+	src := solidRGBA(20, 20, color.RGBA{0, 0, 255, 255}, true)
+
+	p := New(WithPNGToJPEG(true))
+	_, contentType, err := p.Process(encodePNG(t, src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if contentType != "image/png" {
+		t.Fatalf("expected image/png for a transparent source, got %s", contentType)
+	}
+}
+
+func TestPipeline_ConvertsOpaquePNGToJPEGWhenEnabled(t *testing.T) {
+	src := solidRGBA(20, 20, color.RGBA{0, 0, 255, 255}, false)
+
+	p := New(WithPNGToJPEG(true))
+	_, contentType, err := p.Process(encodePNG(t, src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if contentType != "image/jpeg" {
+		t.Fatalf("expected image/jpeg for an opaque source, got %s", contentType)
+	}
+}
+
+func TestPipeline_DecodesWebp(t *testing.T) {
+	f, err := os.Open("testdata/sample.webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	data, contentType, err := Default().Process(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if contentType != "image/jpeg" {
+		t.Fatalf("expected image/jpeg, got %s", contentType)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("expected the webp source to decode to a valid jpeg: %v", err)
+	}
+}
+
+func TestPipeline_KeepsOpaquePNGAsPNGWhenConversionDisabled(t *testing.T) {
+	src := solidRGBA(20, 20, color.RGBA{0, 0, 255, 255}, false)
+
+	p := New(WithPNGToJPEG(false))
+	_, contentType, err := p.Process(encodePNG(t, src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if contentType != "image/png" {
+		t.Fatalf("expected image/png when conversion is disabled, got %s", contentType)
+	}
+}
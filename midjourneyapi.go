@@ -1,13 +1,20 @@
 package midjourneyapi
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
 	"strings"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sintanial/midjourneyapi/imagepipe"
 )
 
 const host = "https://api.midjourneyapi.io/v2"
@@ -31,11 +38,39 @@ type ResultResponse struct {
 }
 
 type Client struct {
-	apiKey string
+	apiKey         string
+	imagePipeline  *imagepipe.Pipeline
+	callbackServer *CallbackServer
+
+	httpClient  *http.Client
+	maxAttempts int
+	backoff     BackoffFunc
+	limiter     *rate.Limiter
+}
+
+// NewClient builds a Client for apiKey, using http.DefaultClient and no
+// retry or rate-limit policy unless overridden by opts.
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		apiKey:     apiKey,
+		httpClient: http.DefaultClient,
+		backoff:    DefaultBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
-func NewClient(apiKey string) *Client {
-	return &Client{apiKey: apiKey}
+// WithImagePipeline sets the pipeline Describe and FaceswapUpload route
+// image bytes through before multipart upload, so callers stay within
+// Midjourney's per-image size limits without encoding images by hand. Pass
+// nil to disable pre-upload processing.
+func (self *Client) WithImagePipeline(p *imagepipe.Pipeline) *Client {
+	self.imagePipeline = p
+	return self
 }
 
 type ImagineRequest struct {
@@ -94,54 +129,102 @@ func escapeQuotes(s string) string {
 	return quoteEscaper.Replace(s)
 }
 
-func (self *Client) Describe(image io.Reader, callbackURL ...string) (string, error) {
-	var reqbody bytes.Buffer
-	mw := multipart.NewWriter(&reqbody)
+// ErrUnsupportedImageType is returned when the sniffed content type of an
+// uploaded image is not one Midjourney accepts (jpg/png/webp/gif).
+var ErrUnsupportedImageType = errors.New("midjourneyapi: unsupported image type")
 
-	h := make(textproto.MIMEHeader)
-	h.Set("Content-Disposition", `form-data; name="image"; filename="image.jpg"`)
-
-	// todo: add correct mime type detection by read Peek bytes from image
-	h.Set("Content-Type", "image/jpeg")
+var imageExtensionByMimeType = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+	"image/gif":  "gif",
+}
 
-	w, err := mw.CreatePart(h)
-	if err != nil {
-		return "", err
+// detectImageType peeks at the first bytes of r to determine the media type
+// and a matching file extension, without consuming the reader.
+func detectImageType(r *bufio.Reader) (mimeType string, ext string, err error) {
+	peek, err := r.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", "", err
 	}
 
-	if _, err := io.Copy(w, image); err != nil {
-		return "", err
+	mimeType = http.DetectContentType(peek)
+
+	ext, ok := imageExtensionByMimeType[mimeType]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrUnsupportedImageType, mimeType)
 	}
 
-	if len(callbackURL) >= 1 {
-		if err := mw.WriteField("callbackURL", callbackURL[0]); err != nil {
-			return "", err
+	return mimeType, ext, nil
+}
+
+// prepareImage runs image through the client's configured pipeline, if any,
+// falling back to plain content-type sniffing. It returns the bytes to
+// upload along with their MIME type and a matching file extension.
+func (self *Client) prepareImage(image io.Reader) (data []byte, mimeType string, ext string, err error) {
+	br := bufio.NewReader(image)
+
+	if self.imagePipeline != nil {
+		data, mimeType, err = self.imagePipeline.Process(br)
+		if err != nil {
+			return nil, "", "", err
 		}
+
+		return data, mimeType, imageExtensionByMimeType[mimeType], nil
 	}
 
-	if err := mw.Close(); err != nil {
-		return "", err
+	mimeType, ext, err = detectImageType(br)
+	if err != nil {
+		return nil, "", "", err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, host+"/describe", &reqbody)
+	data, err = io.ReadAll(br)
 	if err != nil {
-		return "", err
+		return nil, "", "", err
 	}
-	req.Header.Set("Content-Type", mw.FormDataContentType())
-	req.Header.Set("Authorization", self.apiKey)
 
-	res, err := http.DefaultClient.Do(req)
+	return data, mimeType, ext, nil
+}
+
+func writeImagePart(mw *multipart.Writer, fieldName string, mimeType string, ext string, data []byte) error {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="image.%s"`, escapeQuotes(fieldName), ext))
+	h.Set("Content-Type", mimeType)
+
+	w, err := mw.CreatePart(h)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	resbody, err := io.ReadAll(res.Body)
+	_, err = w.Write(data)
+	return err
+}
+
+func (self *Client) Describe(image io.Reader, callbackURL ...string) (string, error) {
+	data, mimeType, ext, err := self.prepareImage(image)
 	if err != nil {
 		return "", err
 	}
 
+	var reqbody bytes.Buffer
+	mw := multipart.NewWriter(&reqbody)
+
+	if err := writeImagePart(mw, "image", mimeType, ext, data); err != nil {
+		return "", err
+	}
+
+	if len(callbackURL) >= 1 {
+		if err := mw.WriteField("callbackURL", callbackURL[0]); err != nil {
+			return "", err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
 	var result DescribeResponse
-	if err := json.Unmarshal(resbody, &result); err != nil {
+	if err := self.postMultipart("/describe", &reqbody, mw.FormDataContentType(), &result); err != nil {
 		return "", err
 	}
 
@@ -239,6 +322,55 @@ func (self *Client) Faceswap(targetImageURL string, faceImageURL string) (string
 	return result.ImageURL, err
 }
 
+type FaceswapUploadResponse struct {
+	TaskId string `json:"taskId"`
+}
+
+// FaceswapUpload submits target and face images directly as file uploads
+// instead of URLs, routing both through the configured image pipeline (if
+// any) before multipart upload, and returns the resulting task ID. The
+// "target_image"/"face_image" field names and the reuse of the existing
+// /faceswap path are not yet confirmed against the live API (see
+// TestClient_FaceswapUpload) — adjust them if the API responds with a 4xx.
+func (self *Client) FaceswapUpload(target io.Reader, face io.Reader, callbackURL ...string) (string, error) {
+	targetData, targetMimeType, targetExt, err := self.prepareImage(target)
+	if err != nil {
+		return "", err
+	}
+
+	faceData, faceMimeType, faceExt, err := self.prepareImage(face)
+	if err != nil {
+		return "", err
+	}
+
+	var reqbody bytes.Buffer
+	mw := multipart.NewWriter(&reqbody)
+
+	if err := writeImagePart(mw, "target_image", targetMimeType, targetExt, targetData); err != nil {
+		return "", err
+	}
+	if err := writeImagePart(mw, "face_image", faceMimeType, faceExt, faceData); err != nil {
+		return "", err
+	}
+
+	if len(callbackURL) >= 1 {
+		if err := mw.WriteField("callbackURL", callbackURL[0]); err != nil {
+			return "", err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	var result FaceswapUploadResponse
+	if err := self.postMultipart("/faceswap", &reqbody, mw.FormDataContentType(), &result); err != nil {
+		return "", err
+	}
+
+	return result.TaskId, nil
+}
+
 func (self *Client) postJson(path string, request interface{}, response interface{}) error {
 	var body bytes.Buffer
 	if err := json.NewEncoder(&body).Encode(request); err != nil {
@@ -252,14 +384,34 @@ func (self *Client) postJson(path string, request interface{}, response interfac
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", self.apiKey)
 
-	res, err := http.DefaultClient.Do(req)
+	resbody, res, err := self.do(req)
+	if err != nil {
+		return err
+	}
+
+	if err := checkResponse(res, resbody); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(resbody, response)
+}
+
+func (self *Client) postMultipart(path string, body *bytes.Buffer, contentType string, response interface{}) error {
+	req, err := http.NewRequest(http.MethodPost, host+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", self.apiKey)
+
+	resbody, res, err := self.do(req)
 	if err != nil {
 		return err
 	}
 
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+	if err := checkResponse(res, resbody); err != nil {
 		return err
 	}
 
-	return nil
+	return json.Unmarshal(resbody, response)
 }
@@ -1,8 +1,16 @@
 package midjourneyapi
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -32,3 +40,158 @@ func TestClient_DescribeResult(t *testing.T) {
 
 	fmt.Println(result)
 }
+
+// TestClient_FaceswapUpload is a smoke test against the live API: the
+// "target_image"/"face_image" field names FaceswapUpload posts to /faceswap
+// have not been confirmed against Midjourney's documentation, so this is
+// the check to run (with a real API_KEY and testdata fixtures) before
+// trusting the wire shape.
+func TestClient_FaceswapUpload(t *testing.T) {
+	client := NewClient(apiKey)
+
+	target, err := os.Open("testdata/example.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+
+	face, err := os.Open("testdata/face.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer face.Close()
+
+	result, err := client.FaceswapUpload(target, face)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fmt.Println(result)
+}
+
+func TestDetectImageType(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     []byte
+		wantMime string
+		wantExt  string
+		wantErr  bool
+	}{
+		{"jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE0}, "image/jpeg", "jpg", false},
+		{"png", []byte{0x89, 'P', 'N', 'G', 0x0D, 0x0A, 0x1A, 0x0A}, "image/png", "png", false},
+		{"gif", []byte("GIF89a"), "image/gif", "gif", false},
+		{"webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), "image/webp", "webp", false},
+		{"pdf", []byte("%PDF-1.4"), "", "", true},
+		{"plain text", []byte("just some text, nothing image-like here"), "", "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mimeType, ext, err := detectImageType(bufio.NewReader(bytes.NewReader(tc.data)))
+			if tc.wantErr {
+				if !errors.Is(err, ErrUnsupportedImageType) {
+					t.Fatalf("expected ErrUnsupportedImageType, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mimeType != tc.wantMime || ext != tc.wantExt {
+				t.Fatalf("got (%s, %s), want (%s, %s)", mimeType, ext, tc.wantMime, tc.wantExt)
+			}
+		})
+	}
+}
+
+// capturingTransport records the Content-Type and filename of the first
+// multipart part of the request it receives, instead of hitting the network.
+type capturingTransport struct {
+	contentType string
+	filename    string
+}
+
+func (c *capturingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("expected a multipart request, got %q", mediaType)
+	}
+
+	part, err := multipart.NewReader(req.Body, params["boundary"]).NextPart()
+	if err != nil {
+		return nil, err
+	}
+
+	c.contentType = part.Header.Get("Content-Type")
+
+	_, cdParams, err := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+	if err != nil {
+		return nil, err
+	}
+	c.filename = cdParams["filename"]
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"taskId":"task-1"}`)),
+	}, nil
+}
+
+func TestClient_Describe_SetsContentTypeAndFilenameExtension(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    []byte
+		wantExt string
+	}{
+		{"jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0, 0, 0}, "jpg"},
+		{"png", []byte{0x89, 'P', 'N', 'G', 0x0D, 0x0A, 0x1A, 0x0A}, "png"},
+		{"gif", []byte("GIF89a" + strings.Repeat("x", 16)), "gif"},
+		{"webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 " + strings.Repeat("x", 16)), "webp"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &capturingTransport{}
+			client := NewClient("key", WithHTTPClient(&http.Client{Transport: transport}))
+
+			taskId, err := client.Describe(bytes.NewReader(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if taskId != "task-1" {
+				t.Fatalf("unexpected taskId: %s", taskId)
+			}
+
+			wantCT := imageExtensionToMimeType(tc.wantExt)
+			if transport.contentType != wantCT {
+				t.Fatalf("expected Content-Type %s, got %s", wantCT, transport.contentType)
+			}
+			if transport.filename != "image."+tc.wantExt {
+				t.Fatalf("expected filename image.%s, got %s", tc.wantExt, transport.filename)
+			}
+		})
+	}
+}
+
+func imageExtensionToMimeType(ext string) string {
+	for mimeType, e := range imageExtensionByMimeType {
+		if e == ext {
+			return mimeType
+		}
+	}
+	return ""
+}
+
+func TestClient_Describe_RejectsUnsupportedTypeWithoutMakingARequest(t *testing.T) {
+	transport := &capturingTransport{}
+	client := NewClient("key", WithHTTPClient(&http.Client{Transport: transport}))
+
+	_, err := client.Describe(strings.NewReader("%PDF-1.4 this is not an image"))
+	if !errors.Is(err, ErrUnsupportedImageType) {
+		t.Fatalf("expected ErrUnsupportedImageType, got %v", err)
+	}
+	if transport.contentType != "" {
+		t.Fatal("expected no HTTP request to be made for an unsupported type")
+	}
+}
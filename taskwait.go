@@ -0,0 +1,136 @@
+package midjourneyapi
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// TaskWaiter wraps a Client and polls /result until a task leaves the
+// waiting-to-start/running states, handling exponential backoff and
+// context cancellation so callers don't have to write that loop themselves.
+type TaskWaiter struct {
+	client *Client
+
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	jitter          float64
+
+	// OnProgress, if set, is called after every poll with the task's current
+	// status and percentage.
+	OnProgress func(status string, pct float64)
+}
+
+type TaskWaiterOption func(*TaskWaiter)
+
+// WithInitialInterval sets the delay before the first retry poll.
+func WithInitialInterval(d time.Duration) TaskWaiterOption {
+	return func(w *TaskWaiter) { w.initialInterval = d }
+}
+
+// WithMaxInterval caps how long the backoff is allowed to grow between polls.
+func WithMaxInterval(d time.Duration) TaskWaiterOption {
+	return func(w *TaskWaiter) { w.maxInterval = d }
+}
+
+// WithJitter sets the fraction (0-1) of random jitter applied to each
+// interval, to avoid many waiters polling in lockstep.
+func WithJitter(jitter float64) TaskWaiterOption {
+	return func(w *TaskWaiter) { w.jitter = jitter }
+}
+
+// WithOnProgress sets the hook called after every poll with the task's
+// current status and percentage.
+func WithOnProgress(fn func(status string, pct float64)) TaskWaiterOption {
+	return func(w *TaskWaiter) { w.OnProgress = fn }
+}
+
+// NewTaskWaiter builds a TaskWaiter around client, polling every second by
+// default, backing off up to 30 seconds, with 25% jitter.
+func NewTaskWaiter(client *Client, opts ...TaskWaiterOption) *TaskWaiter {
+	w := &TaskWaiter{
+		client:          client,
+		initialInterval: time.Second,
+		maxInterval:     30 * time.Second,
+		jitter:          0.25,
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+func (w *TaskWaiter) isDone(status string) bool {
+	return status != StatusWaitingToStart && status != StatusRunning
+}
+
+func (w *TaskWaiter) nextDelay(interval time.Duration) time.Duration {
+	if w.jitter <= 0 {
+		return interval
+	}
+
+	delta := float64(interval) * w.jitter
+	return interval + time.Duration((rand.Float64()*2-1)*delta)
+}
+
+// waitFor polls fetch until its result's status leaves the
+// waiting-to-start/running states, or ctx is done.
+func waitFor[T any](ctx context.Context, w *TaskWaiter, fetch func() (*T, error), progress func(*T) (string, float64)) (*T, error) {
+	interval := w.initialInterval
+
+	for {
+		result, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+
+		status, pct := progress(result)
+		if w.OnProgress != nil {
+			w.OnProgress(status, pct)
+		}
+
+		if w.isDone(status) {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(w.nextDelay(interval)):
+		}
+
+		interval *= 2
+		if interval > w.maxInterval {
+			interval = w.maxInterval
+		}
+	}
+}
+
+// WaitImagine polls ImagineResult until taskId finishes or ctx is done.
+func (w *TaskWaiter) WaitImagine(ctx context.Context, taskId string) (*ImagineResultResponse, error) {
+	return waitFor(ctx, w, func() (*ImagineResultResponse, error) {
+		return w.client.ImagineResult(taskId)
+	}, func(r *ImagineResultResponse) (string, float64) {
+		return r.Status, r.Percentage
+	})
+}
+
+// WaitDescribe polls DescribeResult until taskId finishes or ctx is done.
+func (w *TaskWaiter) WaitDescribe(ctx context.Context, taskId string) (*DescribeResultResponse, error) {
+	return waitFor(ctx, w, func() (*DescribeResultResponse, error) {
+		return w.client.DescribeResult(taskId)
+	}, func(r *DescribeResultResponse) (string, float64) {
+		return r.Status, r.Percentage
+	})
+}
+
+// WaitSeed polls SeedResult until taskId finishes or ctx is done.
+func (w *TaskWaiter) WaitSeed(ctx context.Context, taskId string) (*SeedResultResponse, error) {
+	return waitFor(ctx, w, func() (*SeedResultResponse, error) {
+		return w.client.SeedResult(taskId)
+	}, func(r *SeedResultResponse) (string, float64) {
+		return r.Status, r.Percentage
+	})
+}
@@ -0,0 +1,56 @@
+package midjourneyapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaskWaiter_WaitForReturnsOnTerminalStatus(t *testing.T) {
+	w := NewTaskWaiter(nil, WithInitialInterval(time.Millisecond), WithMaxInterval(time.Millisecond))
+
+	calls := 0
+	var progress []string
+	w.OnProgress = func(status string, pct float64) { progress = append(progress, status) }
+
+	result, err := waitFor(context.Background(), w, func() (*ResultResponse, error) {
+		calls++
+		status := StatusRunning
+		if calls == 3 {
+			status = "finished"
+		}
+		return &ResultResponse{Status: status, Percentage: float64(calls) * 10}, nil
+	}, func(r *ResultResponse) (string, float64) {
+		return r.Status, r.Percentage
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Status != "finished" {
+		t.Fatalf("expected finished, got %s", result.Status)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+	if len(progress) != 3 {
+		t.Fatalf("expected 3 progress updates, got %d", len(progress))
+	}
+}
+
+func TestTaskWaiter_WaitForPropagatesContextCancellation(t *testing.T) {
+	w := NewTaskWaiter(nil, WithInitialInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := waitFor(ctx, w, func() (*ResultResponse, error) {
+		return &ResultResponse{Status: StatusRunning}, nil
+	}, func(r *ResultResponse) (string, float64) {
+		return r.Status, r.Percentage
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}